@@ -35,6 +35,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -137,7 +138,7 @@ func (u *UserSettings) GetStrict(alias, key string) (string, error) {
 			filename = u.userConfigFinder()
 		}
 		var err error
-		u.userConfig, err = parseFile(filename)
+		u.userConfig, err = parseFile(filename, false)
 		if err != nil && os.IsNotExist(err) == false {
 			u.onceErr = err
 			return
@@ -147,7 +148,7 @@ func (u *UserSettings) GetStrict(alias, key string) (string, error) {
 		} else {
 			filename = u.systemConfigFinder()
 		}
-		u.systemConfig, err = parseFile(filename)
+		u.systemConfig, err = parseFile(filename, true)
 		if err != nil && os.IsNotExist(err) == false {
 			u.onceErr = err
 			return
@@ -163,18 +164,56 @@ func (u *UserSettings) GetStrict(alias, key string) (string, error) {
 	return findVal(u.systemConfig, alias, key)
 }
 
-func parseFile(filename string) (*Config, error) {
+func parseFile(filename string, system bool) (*Config, error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	return Decode(f)
+	return DecodeWithOptions(f, &DecodeOptions{
+		BaseDir:      filepath.Dir(filename),
+		SystemConfig: system,
+	})
 }
 
 // Decode reads r into a Config, or returns an error if r could not be parsed as
-// an SSH config file.
+// an SSH config file. Include directives (if any) are resolved relative to
+// $HOME/.ssh when evaluated with Get; use DecodeWithOptions to change that.
 func Decode(r io.Reader) (c *Config, err error) {
+	return DecodeWithOptions(r, nil)
+}
+
+// IncludeDepthLimit is the default limit on how many levels of nested
+// Include directives will be followed before Config.Get gives up with an
+// error. It mirrors the kind of guard OpenSSH itself applies to avoid
+// runaway recursion on a pathological config.
+const IncludeDepthLimit = 16
+
+// DecodeOptions controls how DecodeWithOptions parses a config file, in
+// particular how Include directives are resolved once the Config is used
+// with Get.
+type DecodeOptions struct {
+	// BaseDir is the directory that relative Include patterns are resolved
+	// against. If empty, it defaults to the directory of $HOME/.ssh/config,
+	// or /etc/ssh if SystemConfig is true.
+	BaseDir string
+	// SystemConfig marks the file being decoded as a system configuration
+	// file (e.g. /etc/ssh/ssh_config) rather than a user one, which changes
+	// the default BaseDir used to resolve relative Include patterns.
+	SystemConfig bool
+	// DisableIncludes turns off Include resolution entirely. Config.Get
+	// returns an error if it encounters an Include directive while this is
+	// set, instead of trying to read the included file(s).
+	DisableIncludes bool
+	// MaxIncludeDepth caps how many levels of nested Includes Get will
+	// follow before returning an error. Zero means IncludeDepthLimit.
+	MaxIncludeDepth int
+}
+
+// DecodeWithOptions reads r into a Config the same way Decode does, but lets
+// the caller control how Include directives encountered later (via
+// Config.Get) are resolved. A nil opts is equivalent to &DecodeOptions{}.
+func DecodeWithOptions(r io.Reader, opts *DecodeOptions) (c *Config, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			if _, ok := r.(runtime.Error); ok {
@@ -185,6 +224,9 @@ func Decode(r io.Reader) (c *Config, err error) {
 	}()
 
 	c = parseSSH(lexSSH(r))
+	if opts != nil {
+		c.opts = *opts
+	}
 	return c, err
 }
 
@@ -194,6 +236,9 @@ type Config struct {
 	// A list of hosts to match against. The file begins with an implicit
 	// "Host *" declaration matching all hosts.
 	Hosts []*Host
+	// opts controls how Include directives found in this Config (or any
+	// Config it includes) are resolved by Get.
+	opts DecodeOptions
 }
 
 // Get finds the first value in the configuration that matches the alias and
@@ -202,14 +247,45 @@ type Config struct {
 //
 // The match for key is case insensitive.
 //
-// Get is a wrapper around DefaultUserSettings.Get.
+// Get is a thin wrapper around GetWithContext: it fills in User from
+// os/user.Current() and leaves Exec nil, so Match "exec" criteria never
+// match and no external commands are run. Use GetWithContext directly to
+// control those.
 func (c *Config) Get(alias, key string) (string, error) {
+	return c.GetWithContext(defaultMatchContext(alias), key)
+}
+
+// defaultMatchContext builds the MatchContext that Get, GetEffective, and
+// GetAll use: alias for both Alias and OriginalHost, and User/LocalUser
+// from the current OS user, if available. Exec is left nil.
+func defaultMatchContext(alias string) MatchContext {
+	ctx := MatchContext{Alias: alias, OriginalHost: alias}
+	if u, err := osuser.Current(); err == nil {
+		ctx.User = u.Username
+		ctx.LocalUser = u.Username
+	}
+	return ctx
+}
+
+// GetWithContext behaves like Get, but evaluates Match directives (and the
+// "host"/"originalhost"/"user"/"localuser"/"exec" criteria they can carry)
+// against the given ctx instead of the default one Get builds. See
+// MatchContext for what each field feeds into.
+func (c *Config) GetWithContext(ctx MatchContext, key string) (string, error) {
+	return c.getWithContext(ctx, key, map[string]bool{}, 0)
+}
+
+func (c *Config) getWithContext(ctx MatchContext, key string, visited map[string]bool, depth int) (string, error) {
 	lowerKey := strings.ToLower(key)
-	for _, host := range c.Hosts {
-		if !host.Matches(alias) {
+	for _, block := range c.Blocks() {
+		ok, err := block.blockMatches(ctx)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
 			continue
 		}
-		for _, node := range host.Nodes {
+		for _, node := range block.blockNodes() {
 			switch t := node.(type) {
 			case *Empty:
 				continue
@@ -217,10 +293,14 @@ func (c *Config) Get(alias, key string) (string, error) {
 				// "keys are case insensitive" per the spec
 				lkey := strings.ToLower(t.Key)
 				if lkey == "include" {
-					panic("can't handle Include directives")
-				}
-				if lkey == "match" {
-					panic("can't handle Match directives")
+					val, err := c.resolveInclude(ctx, t.Value, lowerKey, visited, depth)
+					if err != nil {
+						return "", err
+					}
+					if val != "" {
+						return val, nil
+					}
+					continue
 				}
 				if lkey == lowerKey {
 					return t.Value, nil
@@ -233,6 +313,244 @@ func (c *Config) Get(alias, key string) (string, error) {
 	return "", nil
 }
 
+// GetEffective returns the merged effective configuration for alias: every
+// Host block (following Include and Match the same way Get does) whose
+// patterns match alias is walked in file order, and the first value seen
+// for each key wins, even if a later block would otherwise be considered a
+// better match. This mirrors ssh's own merge behavior, where the set of
+// matched blocks forms one composite configuration rather than the first
+// match winning outright. Map keys are lowercased.
+func (c *Config) GetEffective(alias string) (map[string]string, error) {
+	ctx := defaultMatchContext(alias)
+	effective := map[string]string{}
+	err := c.eachValue(ctx, map[string]bool{}, 0, func(key, value string) (bool, error) {
+		lkey := strings.ToLower(key)
+		if _, ok := effective[lkey]; !ok {
+			effective[lkey] = value
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return effective, nil
+}
+
+// multiValuedKeys holds the (lowercased) directives that ssh accumulates
+// across every matched block instead of taking only the first occurrence,
+// per ssh_config(5). GetAll is meant for these.
+var multiValuedKeys = map[string]bool{
+	"identityfile":    true,
+	"localforward":    true,
+	"remoteforward":   true,
+	"sendenv":         true,
+	"setenv":          true,
+	"certificatefile": true,
+}
+
+// GetAll returns every value for key across the Host blocks that match
+// alias, in file order, following Include and Match the same way Get does.
+// It's meant for directives ssh accumulates rather than overrides - see
+// multiValuedKeys - but makes no attempt to stop the caller from asking for
+// others.
+func (c *Config) GetAll(alias, key string) ([]string, error) {
+	ctx := defaultMatchContext(alias)
+	lowerKey := strings.ToLower(key)
+	var values []string
+	err := c.eachValue(ctx, map[string]bool{}, 0, func(k, value string) (bool, error) {
+		if strings.ToLower(k) == lowerKey {
+			values = append(values, value)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// eachValue walks every Host block matching ctx.Alias in file order,
+// resolving Include directives and honoring Match scoping the same way
+// getWithContext does, and calls fn for every key/value pair found. fn
+// returns false to stop the walk early.
+func (c *Config) eachValue(ctx MatchContext, visited map[string]bool, depth int, fn func(key, value string) (bool, error)) error {
+	for _, block := range c.Blocks() {
+		ok, err := block.blockMatches(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		for _, node := range block.blockNodes() {
+			kv, ok := node.(*KV)
+			if !ok {
+				continue
+			}
+			lkey := strings.ToLower(kv.Key)
+			if lkey == "include" {
+				stop := false
+				err := c.forEachIncludedConfig(kv.Value, visited, depth, func(included *Config) error {
+					err := included.eachValue(ctx, visited, depth+1, func(k, v string) (bool, error) {
+						cont, err := fn(k, v)
+						if !cont {
+							stop = true
+						}
+						return cont, err
+					})
+					if stop {
+						return errStopIncludeWalk
+					}
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				if stop {
+					return nil
+				}
+				continue
+			}
+			cont, err := fn(kv.Key, kv.Value)
+			if err != nil {
+				return err
+			}
+			if !cont {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// errStopIncludeWalk is an internal sentinel a forEachIncludedConfig
+// callback returns to stop visiting further included files once it has
+// what it needs; it's never returned to a caller outside this file.
+var errStopIncludeWalk = errors.New("ssh_config: stop")
+
+// forEachIncludedConfig resolves pattern (an Include directive's value) and
+// calls fn with each matched file's parsed Config, in file order. It tracks
+// the absolute paths of files currently being resolved in visited, so that
+// a file that (directly or transitively) includes itself is reported as an
+// error rather than recursing forever, and enforces c.opts.MaxIncludeDepth.
+// fn returning errStopIncludeWalk ends the walk early without propagating
+// an error; any other error aborts it and is returned as-is.
+func (c *Config) forEachIncludedConfig(pattern string, visited map[string]bool, depth int, fn func(*Config) error) error {
+	if c.opts.DisableIncludes {
+		return fmt.Errorf("ssh_config: Include %q encountered but Include resolution is disabled", pattern)
+	}
+	maxDepth := c.opts.MaxIncludeDepth
+	if maxDepth == 0 {
+		maxDepth = IncludeDepthLimit
+	}
+	if depth >= maxDepth {
+		return fmt.Errorf("ssh_config: Include %q exceeds max depth of %d", pattern, maxDepth)
+	}
+	paths, err := resolveIncludePaths(pattern, c.opts)
+	if err != nil {
+		return fmt.Errorf("ssh_config: Include %q: %w", pattern, err)
+	}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if visited[abs] {
+			return fmt.Errorf("ssh_config: Include cycle detected at %q", abs)
+		}
+		included, err := parseFile(abs, c.opts.SystemConfig)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		included.opts = c.opts
+		included.opts.BaseDir = filepath.Dir(abs)
+
+		visited[abs] = true
+		err = fn(included)
+		delete(visited, abs)
+		if err == errStopIncludeWalk {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveInclude expands the Include pattern found in c, in file order,
+// descending into each matched file to look for alias/key the same way Get
+// does.
+func (c *Config) resolveInclude(ctx MatchContext, pattern, lowerKey string, visited map[string]bool, depth int) (string, error) {
+	var found string
+	err := c.forEachIncludedConfig(pattern, visited, depth, func(included *Config) error {
+		val, err := included.getWithContext(ctx, lowerKey, visited, depth+1)
+		if err != nil {
+			return err
+		}
+		if val != "" {
+			found = val
+			return errStopIncludeWalk
+		}
+		return nil
+	})
+	return found, err
+}
+
+// resolveIncludePaths expands an Include pattern into the list of absolute
+// file paths it refers to: "~" is expanded to the user's home directory,
+// relative paths are resolved against opts.BaseDir (falling back to
+// ~/.ssh or /etc/ssh, per opts.SystemConfig), and the result is passed
+// through filepath.Glob. A pattern matching no files is not an error, per
+// ssh_config(5).
+func resolveIncludePaths(pattern string, opts DecodeOptions) ([]string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	expanded, err := expandUser(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(expanded) {
+		base := opts.BaseDir
+		if base == "" {
+			if opts.SystemConfig {
+				base = filepath.Dir(systemConfigFinder())
+			} else {
+				base = filepath.Dir(userConfigFinder())
+			}
+		}
+		expanded = filepath.Join(base, expanded)
+	}
+	matches, err := filepath.Glob(expanded)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// expandUser expands a leading "~" in path to the current user's home
+// directory.
+func expandUser(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	var home string
+	if u, err := osuser.Current(); err == nil {
+		home = u.HomeDir
+	} else if h := os.Getenv("HOME"); h != "" {
+		home = h
+	} else {
+		return "", errors.New("could not determine home directory to expand ~")
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
 // String returns a string representation of the Config file.
 func (c *Config) String() string {
 	var buf bytes.Buffer
@@ -375,6 +693,308 @@ func (h *Host) String() string {
 	return buf.String()
 }
 
+// MatchContext carries the information a Match directive's criteria are
+// evaluated against, for use with Config.GetWithContext.
+type MatchContext struct {
+	// Alias is the host alias as given by the caller (ssh's argv[1] or an
+	// Host/OriginalHost value). Host block patterns match against it, and
+	// so do "host" criteria when Host is empty. "originalhost" criteria
+	// match OriginalHost, not Alias.
+	Alias string
+	// OriginalHost is the alias before any HostName substitution. It's
+	// usually the same value as Alias; callers that have already resolved
+	// HostName can set Alias to the resolved name and OriginalHost to the
+	// alias the user actually typed.
+	OriginalHost string
+	// Host is the target hostname after HostName substitution, if known.
+	// "host" criteria match against this, falling back to Alias if empty.
+	Host string
+	// User is the SSH user that will be used for the connection. "user"
+	// criteria match against this.
+	User string
+	// LocalUser is the user running the ssh client. "localuser" criteria
+	// match against this.
+	LocalUser string
+	// Canonical marks that hostname canonicalization has already run, for
+	// "canonical" criteria.
+	Canonical bool
+	// Exec, if non-nil, is invoked for "exec" criteria with the raw
+	// command string; a nil error and true result means the command
+	// exited 0. A nil Exec (the default used by Get) means "exec" criteria
+	// never match, so evaluating a Match never runs an external command
+	// unless the caller opts in.
+	Exec func(command string) (bool, error)
+}
+
+// MatchCriterion is a single keyword and its arguments within a Match
+// directive, e.g. the "host example.*" in "Match host example.* user root".
+type MatchCriterion struct {
+	// Keyword is lowercased: one of "host", "originalhost", "user",
+	// "localuser", "exec", "all", "canonical".
+	Keyword string
+	// Args holds the keyword's arguments. "host", "originalhost", "user",
+	// and "localuser" split a comma-separated list of patterns into Args;
+	// "exec" has exactly one element, the command; "all" and "canonical"
+	// have none.
+	Args []string
+}
+
+// Match represents a parsed Match directive and the nodes it scopes: the
+// KV/Empty lines that followed it in its enclosing Host, up to the next
+// Match line or the end of the Host. A "Match ..." line is still stored as
+// a KV node (Key == "Match") within the enclosing Host's Nodes, the same
+// way Include is, so Config.String still round-trips byte-for-byte; but
+// Config.Blocks splits each Host's Nodes apart at those Match lines into
+// independent Blocks, because that's how ssh itself treats them: a Match
+// line ends the Host block it appears in, the same way a later Host line
+// would, rather than only applying when the enclosing Host's own patterns
+// also match.
+type Match struct {
+	Criteria []MatchCriterion
+	// Nodes holds the KV/Empty lines scoped to this Match, in file order.
+	Nodes []Node
+
+	raw          string // the KV.Value this Match was parsed from, for String
+	leadingSpace uint16
+	comment      string
+	parseErr     error
+}
+
+// String prints m as it was parsed in the config file.
+func (m *Match) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(strings.Repeat(" ", int(m.leadingSpace)))
+	buf.WriteString("Match ")
+	buf.WriteString(m.raw)
+	if m.comment != "" {
+		buf.WriteString(" #")
+		buf.WriteString(m.comment)
+	}
+	buf.WriteByte('\n')
+	for i := range m.Nodes {
+		buf.WriteString(m.Nodes[i].String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// blockMatches reports whether m's criteria hold against ctx, satisfying
+// Block.
+func (m *Match) blockMatches(ctx MatchContext) (bool, error) {
+	if m.parseErr != nil {
+		return false, m.parseErr
+	}
+	return m.Matches(ctx)
+}
+
+// blockNodes returns m.Nodes, satisfying Block.
+func (m *Match) blockNodes() []Node { return m.Nodes }
+
+// Block is a top-level configuration block: either a *Host or a *Match, as
+// returned by Config.Blocks. The interface is sealed - only *Host and
+// *Match implement it - since a block's identity is what determines how it
+// decides whether it applies.
+type Block interface {
+	String() string
+
+	blockMatches(ctx MatchContext) (bool, error)
+	blockNodes() []Node
+}
+
+// blockMatches reports whether h matches ctx.Alias, satisfying Block.
+func (h *Host) blockMatches(ctx MatchContext) (bool, error) {
+	return h.Matches(ctx.Alias), nil
+}
+
+// blockNodes returns h.Nodes, satisfying Block.
+func (h *Host) blockNodes() []Node { return h.Nodes }
+
+// Blocks returns c's top-level blocks - every Host, and every Match nested
+// inside one - in file order. Unlike ranging over c.Hosts and their Nodes
+// directly, a Match block here is independent of the Host it physically
+// followed: in ssh_config, a Match line ends the preceding Host block the
+// same way a new Host line would, so whether its Nodes apply depends only
+// on the Match's own criteria. Blocks is derived from Hosts on every call,
+// so it always reflects the latest mutations (SetHost, Host.Set, etc).
+func (c *Config) Blocks() []Block {
+	var blocks []Block
+	for _, h := range c.Hosts {
+		blocks = append(blocks, splitHostBlocks(h)...)
+	}
+	return blocks
+}
+
+// splitHostBlocks splits h's Nodes into h's own Block (everything up to its
+// first "Match" KV) followed by a Block for each Match found, in the order
+// they appear.
+func splitHostBlocks(h *Host) []Block {
+	own := &Host{
+		Patterns:     h.Patterns,
+		implicit:     h.implicit,
+		EOLComment:   h.EOLComment,
+		hasEquals:    h.hasEquals,
+		leadingSpace: h.leadingSpace,
+	}
+	var matches []Block
+	var current *Match
+	for _, node := range h.Nodes {
+		if kv, ok := node.(*KV); ok && strings.ToLower(kv.Key) == "match" {
+			m, err := ParseMatch(kv.Value)
+			if err != nil {
+				m = &Match{parseErr: fmt.Errorf("ssh_config: Match %q: %w", kv.Value, err)}
+			}
+			m.raw = kv.Value
+			m.leadingSpace = kv.leadingSpace
+			m.comment = kv.Comment
+			current = m
+			matches = append(matches, current)
+			continue
+		}
+		if current != nil {
+			current.Nodes = append(current.Nodes, node)
+		} else {
+			own.Nodes = append(own.Nodes, node)
+		}
+	}
+	return append([]Block{own}, matches...)
+}
+
+// ParseMatch parses the argument to a Match directive, e.g.
+// `host foo user bar exec "something"`, into its Criteria.
+func ParseMatch(value string) (*Match, error) {
+	fields, err := splitMatchFields(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("empty Match directive")
+	}
+	var criteria []MatchCriterion
+	for i := 0; i < len(fields); {
+		keyword := strings.ToLower(fields[i])
+		i++
+		switch keyword {
+		case "all", "canonical":
+			criteria = append(criteria, MatchCriterion{Keyword: keyword})
+		case "host", "originalhost", "user", "localuser":
+			if i >= len(fields) {
+				return nil, fmt.Errorf("Match %q is missing an argument", keyword)
+			}
+			criteria = append(criteria, MatchCriterion{Keyword: keyword, Args: strings.Split(fields[i], ",")})
+			i++
+		case "exec":
+			if i >= len(fields) {
+				return nil, fmt.Errorf("Match %q is missing an argument", keyword)
+			}
+			criteria = append(criteria, MatchCriterion{Keyword: keyword, Args: []string{fields[i]}})
+			i++
+		default:
+			return nil, fmt.Errorf("unknown Match keyword %q", keyword)
+		}
+	}
+	return &Match{Criteria: criteria}, nil
+}
+
+// splitMatchFields splits a Match directive's value on whitespace, treating
+// a double-quoted span (used by "exec") as a single field.
+func splitMatchFields(s string) ([]string, error) {
+	var fields []string
+	i := 0
+	for i < len(s) {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '"' {
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				j++
+			}
+			if j >= len(s) {
+				return nil, errors.New("unterminated quoted string")
+			}
+			fields = append(fields, s[i+1:j])
+			i = j + 1
+			continue
+		}
+		j := i
+		for j < len(s) && s[j] != ' ' {
+			j++
+		}
+		fields = append(fields, s[i:j])
+		i = j
+	}
+	return fields, nil
+}
+
+// Matches reports whether every criterion in m holds against ctx. An empty
+// Match (no criteria) never matches.
+func (m *Match) Matches(ctx MatchContext) (bool, error) {
+	if len(m.Criteria) == 0 {
+		return false, nil
+	}
+	for _, crit := range m.Criteria {
+		ok, err := crit.matches(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (c MatchCriterion) matches(ctx MatchContext) (bool, error) {
+	switch c.Keyword {
+	case "all":
+		return true, nil
+	case "canonical":
+		return ctx.Canonical, nil
+	case "exec":
+		if ctx.Exec == nil {
+			return false, nil
+		}
+		return ctx.Exec(c.Args[0])
+	case "host":
+		host := ctx.Host
+		if host == "" {
+			host = ctx.Alias
+		}
+		return matchesAny(c.Args, host)
+	case "originalhost":
+		return matchesAny(c.Args, ctx.OriginalHost)
+	case "user":
+		return matchesAny(c.Args, ctx.User)
+	case "localuser":
+		return matchesAny(c.Args, ctx.LocalUser)
+	default:
+		return false, fmt.Errorf("unknown Match keyword %q", c.Keyword)
+	}
+}
+
+// matchesAny reports whether value matches any of the (possibly negated,
+// possibly wildcarded) patterns in args, applying the same negation rule as
+// Host patterns: a negated match anywhere causes the whole list to fail.
+func matchesAny(args []string, value string) (bool, error) {
+	matched := false
+	for _, a := range args {
+		p, err := NewPattern(a)
+		if err != nil {
+			return false, err
+		}
+		if p.regex.MatchString(value) {
+			if p.not {
+				return false, nil
+			}
+			matched = true
+		}
+	}
+	return matched, nil
+}
+
 // Node represents a line in a Config.
 type Node interface {
 	Pos() Position
@@ -0,0 +1,95 @@
+package ssh_config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempConfig(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("WriteFile(%s): %v", path, err)
+	}
+}
+
+func decodeFile(t *testing.T, path string) *Config {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", path, err)
+	}
+	defer f.Close()
+	cfg, err := DecodeWithOptions(f, &DecodeOptions{BaseDir: filepath.Dir(path)})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions(%s): %v", path, err)
+	}
+	return cfg
+}
+
+func TestIncludeResolvesNestedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTempConfig(t, filepath.Join(dir, "included"), "Host example\n  Port 2222\n")
+	writeTempConfig(t, filepath.Join(dir, "main"), "Include included\n")
+
+	cfg := decodeFile(t, filepath.Join(dir, "main"))
+	got, err := cfg.Get("example", "Port")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != "2222" {
+		t.Errorf("Get(example, Port) = %q, want %q", got, "2222")
+	}
+}
+
+func TestIncludeCycleIsAnError(t *testing.T) {
+	dir := t.TempDir()
+	writeTempConfig(t, filepath.Join(dir, "a"), "Include b\n")
+	writeTempConfig(t, filepath.Join(dir, "b"), "Include a\n")
+
+	cfg := decodeFile(t, filepath.Join(dir, "a"))
+	_, err := cfg.Get("example", "Port")
+	if err == nil {
+		t.Fatal("Get returned nil error, want an Include cycle error")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Get error = %q, want it to mention a cycle", err.Error())
+	}
+}
+
+func TestIncludeMissingFileIsNotAnError(t *testing.T) {
+	dir := t.TempDir()
+	writeTempConfig(t, filepath.Join(dir, "main"), "Include nonexistent\nHost example\n  Port 22\n")
+
+	cfg := decodeFile(t, filepath.Join(dir, "main"))
+	got, err := cfg.Get("example", "Port")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != "22" {
+		t.Errorf("Get(example, Port) = %q, want %q", got, "22")
+	}
+}
+
+func TestIncludeDepthLimit(t *testing.T) {
+	dir := t.TempDir()
+	// A chain of IncludeDepthLimit+1 includes, one level more than the
+	// default limit (MaxIncludeDepth is left at zero).
+	prev := "end"
+	writeTempConfig(t, filepath.Join(dir, prev), "Host example\n  Port 22\n")
+	for i := 0; i < IncludeDepthLimit+1; i++ {
+		name := filepath.Join(dir, "link"+string(rune('A'+i)))
+		writeTempConfig(t, name, "Include "+prev+"\n")
+		prev = "link" + string(rune('A'+i))
+	}
+
+	cfg := decodeFile(t, filepath.Join(dir, prev))
+	_, err := cfg.Get("example", "Port")
+	if err == nil {
+		t.Fatal("Get returned nil error, want a max-depth error")
+	}
+	if !strings.Contains(err.Error(), "depth") {
+		t.Errorf("Get error = %q, want it to mention depth", err.Error())
+	}
+}
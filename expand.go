@@ -0,0 +1,196 @@
+package ssh_config
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"strings"
+)
+
+// TokenContext carries the values ExpandTokens substitutes into a config
+// value's %-tokens; see the TOKENS section of ssh_config(5).
+type TokenContext struct {
+	// Host is the alias given by the caller, before any HostName
+	// substitution. Expands %n.
+	Host string
+	// Hostname is the target hostname after HostName substitution, if any;
+	// %h falls back to Host when this is empty.
+	Hostname string
+	// Port expands %p.
+	Port string
+	// User is the remote login name. Expands %r.
+	User string
+	// LocalUser is the user running the local ssh client. Expands %u; %i
+	// expands to this user's numeric uid, or to the current process's uid
+	// if LocalUser is empty.
+	LocalUser string
+	// HomeDir is the local user's home directory. Expands %d.
+	HomeDir string
+	// LocalHost is the local machine's hostname. Expands %l as given, and
+	// %L as its first label (without domain).
+	LocalHost string
+}
+
+// pathValuedKeys are the (lowercased) directives whose values are
+// filesystem paths, where GetExpanded also expands a leading "~" against
+// TokenContext.HomeDir, the way ssh itself does, in addition to %-tokens.
+var pathValuedKeys = map[string]bool{
+	"identityfile":       true,
+	"certificatefile":    true,
+	"controlpath":        true,
+	"userknownhostsfile": true,
+}
+
+// GetExpanded is like Get, but runs the result through ExpandTokens using a
+// TokenContext built from alias's own HostName/Port/User (falling back to
+// the current OS user and hostname), and - for path-valued keys like
+// IdentityFile - expands a leading "~" against the user's home directory
+// first.
+func (c *Config) GetExpanded(alias, key string) (string, error) {
+	value, err := c.Get(alias, key)
+	if err != nil || value == "" {
+		return value, err
+	}
+	ctx := TokenContext{Host: alias, Port: "22"}
+	if hostname, err := c.Get(alias, "HostName"); err == nil && hostname != "" {
+		ctx.Hostname = hostname
+	}
+	if port, err := c.Get(alias, "Port"); err == nil && port != "" {
+		ctx.Port = port
+	}
+	if user, err := c.Get(alias, "User"); err == nil && user != "" {
+		ctx.User = user
+	}
+	if u, err := osuser.Current(); err == nil {
+		ctx.LocalUser = u.Username
+		ctx.HomeDir = u.HomeDir
+		if ctx.User == "" {
+			ctx.User = u.Username
+		}
+	}
+	if host, err := os.Hostname(); err == nil {
+		ctx.LocalHost = host
+	}
+	if pathValuedKeys[strings.ToLower(key)] {
+		value = expandHomeDir(value, ctx.HomeDir)
+	}
+	return ExpandTokens(value, ctx)
+}
+
+// expandHomeDir expands a leading "~" (or "~/...") in value against home,
+// the way a shell or ssh itself would for a path-valued option.
+func expandHomeDir(value, home string) string {
+	if home == "" {
+		return value
+	}
+	if value == "~" {
+		return home
+	}
+	if strings.HasPrefix(value, "~/") {
+		return filepath.Join(home, value[2:])
+	}
+	return value
+}
+
+// ExpandTokens expands the %-tokens documented in ssh_config(5)'s TOKENS
+// section within value, using ctx to fill them in: %%, %C, %d, %h, %i, %L,
+// %l, %n, %p, %r, %T, %u. An unrecognized token (e.g. "%z") is an error
+// rather than being passed through unexpanded.
+func ExpandTokens(value string, ctx TokenContext) (string, error) {
+	var buf strings.Builder
+	for i := 0; i < len(value); i++ {
+		b := value[i]
+		if b != '%' {
+			buf.WriteByte(b)
+			continue
+		}
+		i++
+		if i >= len(value) {
+			return "", errors.New("ssh_config: dangling % at end of value")
+		}
+		switch tok := value[i]; tok {
+		case '%':
+			buf.WriteByte('%')
+		case 'd':
+			buf.WriteString(ctx.HomeDir)
+		case 'h':
+			if ctx.Hostname != "" {
+				buf.WriteString(ctx.Hostname)
+			} else {
+				buf.WriteString(ctx.Host)
+			}
+		case 'i':
+			uid, err := ctx.localUID()
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(uid)
+		case 'L':
+			buf.WriteString(firstLabel(ctx.LocalHost))
+		case 'l':
+			buf.WriteString(ctx.LocalHost)
+		case 'n':
+			buf.WriteString(ctx.Host)
+		case 'p':
+			buf.WriteString(ctx.Port)
+		case 'r':
+			buf.WriteString(ctx.User)
+		case 'T':
+			// We don't track an actual tun(4) device; ssh itself prints
+			// "NONE" in the same situation.
+			buf.WriteString("NONE")
+		case 'u':
+			buf.WriteString(ctx.LocalUser)
+		case 'C':
+			hash, err := ctx.hashC()
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(hash)
+		default:
+			return "", fmt.Errorf("ssh_config: unknown token %%%c", tok)
+		}
+	}
+	return buf.String(), nil
+}
+
+// localUID resolves the numeric uid %i expands to: ctx.LocalUser's, or the
+// current process's if LocalUser is empty.
+func (ctx TokenContext) localUID() (string, error) {
+	if ctx.LocalUser == "" {
+		u, err := osuser.Current()
+		if err != nil {
+			return "", fmt.Errorf("ssh_config: resolving %%i: %w", err)
+		}
+		return u.Uid, nil
+	}
+	u, err := osuser.Lookup(ctx.LocalUser)
+	if err != nil {
+		return "", fmt.Errorf("ssh_config: resolving %%i for %q: %w", ctx.LocalUser, err)
+	}
+	return u.Uid, nil
+}
+
+// hashC computes %C, the hex SHA1 hash of "%l%h%p%r" expanded under ctx, per
+// ssh_config(5).
+func (ctx TokenContext) hashC() (string, error) {
+	s, err := ExpandTokens("%l%h%p%r", ctx)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// firstLabel returns host's first dot-separated label, e.g. "foo" for
+// "foo.example.com".
+func firstLabel(host string) string {
+	if i := strings.IndexByte(host, '.'); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
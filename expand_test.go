@@ -0,0 +1,94 @@
+package ssh_config
+
+import "testing"
+
+func TestExpandTokens(t *testing.T) {
+	ctx := TokenContext{
+		Host:      "myhost",
+		Hostname:  "myhost.example.com",
+		Port:      "2222",
+		User:      "alice",
+		LocalUser: "bob",
+		HomeDir:   "/home/bob",
+		LocalHost: "laptop.example.com",
+	}
+	cases := []struct {
+		value string
+		want  string
+	}{
+		{"%%", "%"},
+		{"%d", "/home/bob"},
+		{"%h", "myhost.example.com"},
+		{"%L", "laptop"},
+		{"%l", "laptop.example.com"},
+		{"%n", "myhost"},
+		{"%p", "2222"},
+		{"%r", "alice"},
+		{"%u", "bob"},
+		{"%T", "NONE"},
+		{"%r@%h:%p", "alice@myhost.example.com:2222"},
+	}
+	for _, tc := range cases {
+		got, err := ExpandTokens(tc.value, ctx)
+		if err != nil {
+			t.Errorf("ExpandTokens(%q) error: %v", tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ExpandTokens(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestExpandTokensHFallsBackToHost(t *testing.T) {
+	ctx := TokenContext{Host: "myhost"}
+	got, err := ExpandTokens("%h", ctx)
+	if err != nil {
+		t.Fatalf("ExpandTokens: %v", err)
+	}
+	if got != "myhost" {
+		t.Errorf("ExpandTokens(%%h) = %q, want %q", got, "myhost")
+	}
+}
+
+func TestExpandTokensUnknownToken(t *testing.T) {
+	if _, err := ExpandTokens("%z", TokenContext{}); err == nil {
+		t.Error("ExpandTokens(%z) = nil error, want one")
+	}
+}
+
+func TestExpandTokensDanglingPercent(t *testing.T) {
+	if _, err := ExpandTokens("foo%", TokenContext{}); err == nil {
+		t.Error("ExpandTokens(\"foo%\") = nil error, want one")
+	}
+}
+
+func TestExpandHomeDir(t *testing.T) {
+	cases := []struct {
+		value, home, want string
+	}{
+		{"~", "/home/bob", "/home/bob"},
+		{"~/.ssh/id_rsa", "/home/bob", "/home/bob/.ssh/id_rsa"},
+		{"/already/absolute", "/home/bob", "/already/absolute"},
+		{"~/x", "", "~/x"},
+	}
+	for _, tc := range cases {
+		got := expandHomeDir(tc.value, tc.home)
+		if got != tc.want {
+			t.Errorf("expandHomeDir(%q, %q) = %q, want %q", tc.value, tc.home, got, tc.want)
+		}
+	}
+}
+
+func TestFirstLabel(t *testing.T) {
+	cases := []struct{ host, want string }{
+		{"foo.example.com", "foo"},
+		{"foo", "foo"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		if got := firstLabel(tc.host); got != tc.want {
+			t.Errorf("firstLabel(%q) = %q, want %q", tc.host, got, tc.want)
+		}
+	}
+}
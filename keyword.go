@@ -0,0 +1,366 @@
+package ssh_config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// KeywordType identifies how a Keyword's value should be interpreted by
+// Config.GetTyped and validated by Config.Validate.
+type KeywordType int
+
+const (
+	// KeywordString is a plain, unvalidated string value.
+	KeywordString KeywordType = iota
+	// KeywordBool is ssh_config's "yes"/"no" value, surfaced as a Go bool.
+	KeywordBool
+	// KeywordInt is a plain integer value, surfaced as a Go int.
+	KeywordInt
+	// KeywordDuration is an OpenSSH time value - a plain number of seconds
+	// or spans like "1h30m" - surfaced as a time.Duration.
+	KeywordDuration
+	// KeywordEnum is a string restricted to Keyword.Enum, compared
+	// case-insensitively.
+	KeywordEnum
+	// KeywordMultiValued is a list of strings, surfaced as []string. A
+	// single occurrence's value is split on commas and whitespace unless
+	// Keyword.Accumulates is set, in which case each matched block's
+	// occurrence contributes one element instead (see Config.GetAll).
+	KeywordMultiValued
+)
+
+// Keyword describes one SSH config directive: its canonical spelling, the
+// shape of its value, and (for KeywordEnum) the values it accepts.
+type Keyword struct {
+	// Name is the directive's canonical case, e.g. "ProxyJump".
+	Name string
+	Type KeywordType
+	// Enum holds the accepted values for a KeywordEnum keyword, compared
+	// case-insensitively.
+	Enum []string
+	// Default is the value ssh uses when the keyword isn't set, in the
+	// same string form it would take in a config file. Empty means there's
+	// no meaningful default to report.
+	Default string
+	// Accumulates is true for directives like IdentityFile that take a
+	// value from every matched block instead of just the first.
+	Accumulates bool
+}
+
+// Keywords is the registry GetTyped and Validate consult, keyed by the
+// keyword's lowercased name. It covers many of the directives documented
+// in OpenSSH 9.x's ssh_config(5), but not all of them (e.g.
+// PubkeyAcceptedAlgorithms and SessionType are absent); Validate reports
+// any directive missing from it as unknown. Callers that need one more can
+// add to it directly; Keywords["x"] = Keyword{...}.
+var Keywords = buildKeywordRegistry()
+
+func buildKeywordRegistry() map[string]Keyword {
+	list := []Keyword{
+		{Name: "AddKeysToAgent", Type: KeywordEnum, Enum: []string{"yes", "no", "ask", "confirm"}, Default: "no"},
+		{Name: "AddressFamily", Type: KeywordEnum, Enum: []string{"any", "inet", "inet6"}, Default: "any"},
+		{Name: "BatchMode", Type: KeywordBool, Default: "no"},
+		{Name: "BindAddress", Type: KeywordString},
+		{Name: "BindInterface", Type: KeywordString},
+		{Name: "CanonicalDomains", Type: KeywordMultiValued},
+		{Name: "CanonicalizeFallbackLocal", Type: KeywordBool, Default: "yes"},
+		{Name: "CanonicalizeHostname", Type: KeywordEnum, Enum: []string{"no", "yes", "always"}, Default: "no"},
+		{Name: "CanonicalizeMaxDots", Type: KeywordInt, Default: "1"},
+		{Name: "CanonicalizePermittedCNAMEs", Type: KeywordMultiValued},
+		{Name: "CASignatureAlgorithms", Type: KeywordMultiValued},
+		{Name: "CertificateFile", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "CheckHostIP", Type: KeywordBool, Default: "no"},
+		{Name: "Ciphers", Type: KeywordMultiValued},
+		{Name: "ClearAllForwardings", Type: KeywordBool, Default: "no"},
+		{Name: "Compression", Type: KeywordBool, Default: "no"},
+		{Name: "ConnectionAttempts", Type: KeywordInt, Default: "1"},
+		{Name: "ConnectTimeout", Type: KeywordDuration},
+		{Name: "ControlMaster", Type: KeywordEnum, Enum: []string{"yes", "no", "ask", "auto", "autoask"}, Default: "no"},
+		{Name: "ControlPath", Type: KeywordString},
+		{Name: "ControlPersist", Type: KeywordString, Default: "no"},
+		{Name: "DynamicForward", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "EnableSSHKeysign", Type: KeywordBool, Default: "no"},
+		{Name: "EscapeChar", Type: KeywordString, Default: "~"},
+		{Name: "ExitOnForwardFailure", Type: KeywordBool, Default: "no"},
+		{Name: "FingerprintHash", Type: KeywordEnum, Enum: []string{"md5", "sha256"}, Default: "sha256"},
+		{Name: "ForwardAgent", Type: KeywordBool, Default: "no"},
+		{Name: "ForwardX11", Type: KeywordBool, Default: "no"},
+		{Name: "ForwardX11Timeout", Type: KeywordDuration, Default: "1200"},
+		{Name: "ForwardX11Trusted", Type: KeywordBool, Default: "no"},
+		{Name: "GatewayPorts", Type: KeywordBool, Default: "no"},
+		{Name: "GlobalKnownHostsFile", Type: KeywordMultiValued},
+		{Name: "GSSAPIAuthentication", Type: KeywordBool, Default: "no"},
+		{Name: "HashKnownHosts", Type: KeywordBool, Default: "no"},
+		{Name: "HostbasedAuthentication", Type: KeywordBool, Default: "no"},
+		{Name: "HostKeyAlgorithms", Type: KeywordMultiValued},
+		{Name: "HostKeyAlias", Type: KeywordString},
+		{Name: "HostName", Type: KeywordString},
+		{Name: "IdentitiesOnly", Type: KeywordBool, Default: "no"},
+		{Name: "IdentityAgent", Type: KeywordString},
+		{Name: "IdentityFile", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "IgnoreUnknown", Type: KeywordMultiValued},
+		{Name: "IPQoS", Type: KeywordString},
+		{Name: "KbdInteractiveAuthentication", Type: KeywordBool, Default: "no"},
+		{Name: "KbdInteractiveDevices", Type: KeywordMultiValued},
+		{Name: "KexAlgorithms", Type: KeywordMultiValued},
+		{Name: "LocalCommand", Type: KeywordString},
+		{Name: "LocalForward", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "LogLevel", Type: KeywordEnum, Enum: []string{"QUIET", "FATAL", "ERROR", "INFO", "VERBOSE", "DEBUG", "DEBUG1", "DEBUG2", "DEBUG3"}, Default: "INFO"},
+		{Name: "MACs", Type: KeywordMultiValued},
+		{Name: "NoHostAuthenticationForLocalhost", Type: KeywordBool, Default: "no"},
+		{Name: "NumberOfPasswordPrompts", Type: KeywordInt, Default: "3"},
+		{Name: "PasswordAuthentication", Type: KeywordBool, Default: "yes"},
+		{Name: "PermitLocalCommand", Type: KeywordBool, Default: "no"},
+		{Name: "Port", Type: KeywordInt, Default: "22"},
+		{Name: "PreferredAuthentications", Type: KeywordMultiValued, Default: "gssapi-with-mic,hostbased,publickey,keyboard-interactive,password"},
+		{Name: "ProxyCommand", Type: KeywordString},
+		{Name: "ProxyJump", Type: KeywordMultiValued},
+		{Name: "ProxyUseFdpass", Type: KeywordBool, Default: "no"},
+		{Name: "PubkeyAuthentication", Type: KeywordBool, Default: "yes"},
+		{Name: "RekeyLimit", Type: KeywordString, Default: "default none"},
+		{Name: "RemoteCommand", Type: KeywordString},
+		{Name: "RemoteForward", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "RequestTTY", Type: KeywordEnum, Enum: []string{"no", "yes", "force", "auto"}, Default: "auto"},
+		{Name: "SendEnv", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "ServerAliveCountMax", Type: KeywordInt, Default: "3"},
+		{Name: "ServerAliveInterval", Type: KeywordDuration, Default: "0"},
+		{Name: "SetEnv", Type: KeywordMultiValued, Accumulates: true},
+		{Name: "StreamLocalBindMask", Type: KeywordString, Default: "0177"},
+		{Name: "StreamLocalBindUnlink", Type: KeywordBool, Default: "no"},
+		{Name: "StrictHostKeyChecking", Type: KeywordEnum, Enum: []string{"yes", "no", "accept-new", "off", "ask"}, Default: "ask"},
+		{Name: "TCPKeepAlive", Type: KeywordBool, Default: "yes"},
+		{Name: "Tunnel", Type: KeywordEnum, Enum: []string{"yes", "no", "point-to-point", "ethernet"}, Default: "no"},
+		{Name: "TunnelDevice", Type: KeywordString, Default: "any:any"},
+		{Name: "UpdateHostKeys", Type: KeywordEnum, Enum: []string{"yes", "no", "ask"}, Default: "no"},
+		{Name: "User", Type: KeywordString},
+		{Name: "UserKnownHostsFile", Type: KeywordMultiValued, Default: "~/.ssh/known_hosts ~/.ssh/known_hosts2"},
+		{Name: "VerifyHostKeyDNS", Type: KeywordEnum, Enum: []string{"yes", "no", "ask"}, Default: "no"},
+		{Name: "VisualHostKey", Type: KeywordBool, Default: "no"},
+		{Name: "XAuthLocation", Type: KeywordString},
+	}
+	reg := make(map[string]Keyword, len(list))
+	for _, kw := range list {
+		reg[strings.ToLower(kw.Name)] = kw
+	}
+	return reg
+}
+
+// GetTyped returns the value of key for alias, converted per its Keywords
+// entry: bool for KeywordBool, int for KeywordInt, time.Duration for
+// KeywordDuration, []string for KeywordMultiValued, and string for
+// KeywordString/KeywordEnum. It returns an error if key isn't in Keywords,
+// if a KeywordEnum, KeywordBool, KeywordInt or KeywordDuration keyword has
+// no value and no default, or if the value doesn't parse as its keyword's
+// type. A KeywordString or KeywordMultiValued keyword with no value and no
+// default yields "" or an empty slice and a nil error.
+func (c *Config) GetTyped(alias, key string) (any, error) {
+	kw, ok := Keywords[strings.ToLower(key)]
+	if !ok {
+		return nil, fmt.Errorf("ssh_config: unknown keyword %q", key)
+	}
+
+	if kw.Type == KeywordMultiValued && kw.Accumulates {
+		values, err := c.GetAll(alias, key)
+		if err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	value, err := c.Get(alias, key)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		value = kw.Default
+	}
+
+	switch kw.Type {
+	case KeywordMultiValued:
+		if value == "" {
+			return []string{}, nil
+		}
+		return splitMultiValue(value), nil
+	case KeywordEnum:
+		if value == "" {
+			return "", fmt.Errorf("ssh_config: %s has no value and no default", kw.Name)
+		}
+		for _, allowed := range kw.Enum {
+			if strings.EqualFold(allowed, value) {
+				return allowed, nil
+			}
+		}
+		return nil, fmt.Errorf("ssh_config: %s: %q is not one of %v", kw.Name, value, kw.Enum)
+	case KeywordBool:
+		b, err := parseYesNo(value)
+		if err != nil {
+			return nil, fmt.Errorf("ssh_config: %s: %w", kw.Name, err)
+		}
+		return b, nil
+	case KeywordInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("ssh_config: %s: %w", kw.Name, err)
+		}
+		return n, nil
+	case KeywordDuration:
+		d, err := parseSSHDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("ssh_config: %s: %w", kw.Name, err)
+		}
+		return d, nil
+	default:
+		return value, nil
+	}
+}
+
+// parseSSHDuration parses an OpenSSH "time format" value, as documented in
+// the TIME FORMATS section of ssh_config(5): either a plain integer number
+// of seconds, or one or more concatenated <n><unit> spans (e.g. "1h30m"),
+// where unit is one of s, m, h, d, w (case-insensitive).
+func parseSSHDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, errors.New("empty time value")
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	var total time.Duration
+	for i := 0; i < len(value); {
+		start := i
+		for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return 0, fmt.Errorf("%q is not a valid time value", value)
+		}
+		n, err := strconv.Atoi(value[start:i])
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid time value", value)
+		}
+		if i >= len(value) {
+			return 0, fmt.Errorf("%q is missing a time unit", value)
+		}
+		unit, ok := units[byte(unicode.ToLower(rune(value[i])))]
+		if !ok {
+			return 0, fmt.Errorf("%q has unknown time unit %q", value, value[i])
+		}
+		total += time.Duration(n) * unit
+		i++
+	}
+	return total, nil
+}
+
+// splitMultiValue splits a non-accumulating multi-valued directive's
+// string on commas and whitespace, e.g. "aes128-ctr,aes256-ctr" or
+// "~/.ssh/known_hosts ~/.ssh/known_hosts2".
+func splitMultiValue(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%q is not \"yes\" or \"no\"", value)
+	}
+}
+
+// ValidationError reports a problem Config.Validate found with one
+// directive: an unknown keyword, a value that doesn't match its keyword's
+// enum, or a malformed int/duration/port.
+type ValidationError struct {
+	Position Position
+	Key      string
+	Message  string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %s: %s", e.Position, e.Key, e.Message)
+}
+
+// Validate walks every KV in c (across every Host block, regardless of
+// whether it would match any particular alias) and reports, as
+// ValidationErrors, any keyword Keywords doesn't recognize and any value
+// that doesn't parse as its keyword's type. Include and Match lines are
+// skipped: Validate neither follows Include nor checks Match criteria.
+func (c *Config) Validate() []ValidationError {
+	var errs []ValidationError
+	for _, host := range c.Hosts {
+		for _, node := range host.Nodes {
+			kv, ok := node.(*KV)
+			if !ok {
+				continue
+			}
+			lkey := strings.ToLower(kv.Key)
+			if lkey == "include" || lkey == "match" {
+				continue
+			}
+			kw, ok := Keywords[lkey]
+			if !ok {
+				errs = append(errs, ValidationError{
+					Position: kv.Pos(),
+					Key:      kv.Key,
+					Message:  "unknown keyword",
+				})
+				continue
+			}
+			if err := validateValue(kw, kv.Value); err != nil {
+				errs = append(errs, ValidationError{
+					Position: kv.Pos(),
+					Key:      kv.Key,
+					Message:  err.Error(),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func validateValue(kw Keyword, value string) error {
+	switch kw.Type {
+	case KeywordEnum:
+		for _, allowed := range kw.Enum {
+			if strings.EqualFold(allowed, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %v", value, kw.Enum)
+	case KeywordBool:
+		_, err := parseYesNo(value)
+		return err
+	case KeywordInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid integer", value)
+		}
+		if strings.EqualFold(kw.Name, "Port") && (n < 1 || n > 65535) {
+			return fmt.Errorf("%d is not a valid port", n)
+		}
+		return nil
+	case KeywordDuration:
+		if _, err := parseSSHDuration(value); err != nil {
+			return fmt.Errorf("%q is not a valid time value", value)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
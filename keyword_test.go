@@ -0,0 +1,83 @@
+package ssh_config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSSHDuration(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"0", 0},
+		{"1200", 1200 * time.Second},
+		{"20m", 20 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"2d", 48 * time.Hour},
+		{"1W", 7 * 24 * time.Hour},
+	}
+	for _, tc := range cases {
+		got, err := parseSSHDuration(tc.value)
+		if err != nil {
+			t.Errorf("parseSSHDuration(%q) error: %v", tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseSSHDuration(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestParseSSHDurationInvalid(t *testing.T) {
+	for _, value := range []string{"", "m", "20x", "h1"} {
+		if _, err := parseSSHDuration(value); err == nil {
+			t.Errorf("parseSSHDuration(%q) = nil error, want one", value)
+		}
+	}
+}
+
+func TestValidateDurationAcceptsTimeFormat(t *testing.T) {
+	cfg := &Config{Hosts: []*Host{{
+		Patterns: []*Pattern{mustPattern(t, "*")},
+		Nodes: []Node{
+			&KV{Key: "ForwardX11Timeout", Value: "20m"},
+		},
+	}}}
+	if errs := cfg.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors for \"20m\"", errs)
+	}
+}
+
+func TestGetTypedDurationTimeFormat(t *testing.T) {
+	cfg := &Config{Hosts: []*Host{{
+		Patterns: []*Pattern{mustPattern(t, "*")},
+		Nodes: []Node{
+			&KV{Key: "ForwardX11Timeout", Value: "20m"},
+		},
+	}}}
+	got, err := cfg.GetTyped("example", "ForwardX11Timeout")
+	if err != nil {
+		t.Fatalf("GetTyped: %v", err)
+	}
+	if got != 20*time.Minute {
+		t.Errorf("GetTyped(ForwardX11Timeout) = %v, want %v", got, 20*time.Minute)
+	}
+}
+
+func TestValidateDurationRejectsGarbage(t *testing.T) {
+	cfg := &Config{Hosts: []*Host{{
+		Patterns: []*Pattern{mustPattern(t, "*")},
+		Nodes: []Node{
+			&KV{Key: "ConnectTimeout", Value: "soon"},
+		},
+	}}}
+	errs := cfg.Validate()
+	if len(errs) != 1 {
+		t.Fatalf("Validate() = %v, want exactly one error", errs)
+	}
+	if !strings.Contains(errs[0].Message, "time value") {
+		t.Errorf("Validate() error message = %q, want it to mention a time value", errs[0].Message)
+	}
+}
@@ -0,0 +1,317 @@
+package ssh_config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// managedBlockDir is the directory, relative to the user's ~/.ssh, that
+// ManagedBlock writes its include files into.
+const managedBlockDir = "config.d"
+
+// fenceComment builds the Empty.Comment text of a legacy managed-block
+// marker line, e.g. "# ----- BEGIN name -----".
+func fenceComment(marker, name string) string {
+	return fmt.Sprintf(" ----- %s %s -----", marker, name)
+}
+
+// ManagedBlockHandle manages a named set of Host blocks that live in their
+// own file under ~/.ssh/config.d, referenced from a main config file via an
+// Include directive. Create one with ManagedBlock.
+type ManagedBlockHandle struct {
+	path       string // the main config file, e.g. ~/.ssh/config
+	name       string
+	includeRef string // e.g. "~/.ssh/config.d/name", as written into path
+}
+
+// ManagedBlock returns a handle for the block named name within the config
+// file at path (typically ~/.ssh/config). Call Upsert to write hosts,
+// Remove to tear the block down, or Diff to preview what ensuring path's
+// Include line would change.
+//
+// Unlike a block fenced inline with "# ----- BEGIN name -----" / "# -----
+// END name -----" comments, the managed hosts live in their own file,
+// ~/.ssh/config.d/name, referenced from path with an "Include
+// ~/.ssh/config.d/name" line. That keeps Upsert from ever clobbering hand
+// edits made elsewhere in path. Upsert detects a legacy fenced section for
+// name and migrates it (moving its lines into the include file and
+// deleting the fence) the first time it runs; Remove deletes such a
+// section, fence and contents, without keeping its lines anywhere.
+func ManagedBlock(path, name string) *ManagedBlockHandle {
+	return &ManagedBlockHandle{
+		path:       path,
+		name:       name,
+		includeRef: filepath.Join("~", ".ssh", managedBlockDir, name),
+	}
+}
+
+func (m *ManagedBlockHandle) includeFile() (string, error) {
+	return expandUser(m.includeRef)
+}
+
+// renderHosts serializes hosts the same way Config.String does.
+func renderHosts(hosts []*Host) string {
+	var buf strings.Builder
+	for _, h := range hosts {
+		buf.WriteString(h.String())
+	}
+	return buf.String()
+}
+
+// reconcileMainConfig computes what m's main config file should look like
+// once it has an Include line for m and any legacy fenced block for m.name
+// is migrated out, without writing anything back. It returns the file's
+// current contents, its reconciled contents, and the raw lines recovered
+// from a legacy fence (empty if there wasn't one).
+func (m *ManagedBlockHandle) reconcileMainConfig() (before, after, legacy string, err error) {
+	before, err = readFileOrEmpty(m.path)
+	if err != nil {
+		return "", "", "", err
+	}
+	cfg, err := Decode(strings.NewReader(before))
+	if err != nil {
+		return "", "", "", err
+	}
+	legacy = extractLegacyFence(cfg, m.name)
+	ensureIncludeLine(cfg, m.includeRef)
+	return before, cfg.String(), legacy, nil
+}
+
+// Upsert writes hosts to m's include file - creating it, and
+// ~/.ssh/config.d, if necessary - and ensures m's main config file has an
+// Include line pointing at it, migrating a legacy fenced block for m.name
+// out of the main config first if one is found. changed reports whether
+// either file's contents were modified; both files are left untouched
+// (changed == false) if they already matched.
+func (m *ManagedBlockHandle) Upsert(hosts []*Host) (bool, error) {
+	before, after, legacy, err := m.reconcileMainConfig()
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	if after != before {
+		if err := writeFileAtomic(m.path, after, 0600); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	incPath, err := m.includeFile()
+	if err != nil {
+		return false, err
+	}
+	existing, err := readFileOrEmpty(incPath)
+	if err != nil {
+		return false, err
+	}
+	desiredHosts := renderHosts(hosts)
+	prefix := legacy
+	if prefix == "" {
+		// legacy is only non-empty the one run that actually migrates a
+		// fence out of the main config - by the next run its BEGIN/END
+		// lines are already gone, so there's nowhere left to recover it
+		// from. If the include file already carries a migrated prefix
+		// ahead of the hosts we're about to write, keep it, so Upsert
+		// stays idempotent instead of dropping that prefix on every run
+		// after the first.
+		if strings.HasSuffix(existing, desiredHosts) {
+			prefix = strings.TrimSuffix(existing, desiredHosts)
+		}
+	}
+	desired := prefix + desiredHosts
+	if existing == desired {
+		return changed, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(incPath), 0700); err != nil {
+		return false, err
+	}
+	if err := writeFileAtomic(incPath, desired, 0600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove deletes m's include file and strips its Include line (or legacy
+// fence, if migration never ran) from the main config file.
+func (m *ManagedBlockHandle) Remove() error {
+	incPath, err := m.includeFile()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(incPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	before, err := readFileOrEmpty(m.path)
+	if err != nil {
+		return err
+	}
+	cfg, err := Decode(strings.NewReader(before))
+	if err != nil {
+		return err
+	}
+	extractLegacyFence(cfg, m.name)
+	removeIncludeLine(cfg, m.includeRef)
+	after := cfg.String()
+	if after == before {
+		return nil
+	}
+	return writeFileAtomic(m.path, after, 0600)
+}
+
+// Diff previews the change Upsert would make to m's main
+// config file - ensuring it contains an Include line for m and migrating
+// any legacy fenced block for m.name - without writing anything. The empty
+// string means the main config file already looks right.
+func (m *ManagedBlockHandle) Diff() (string, error) {
+	before, after, _, err := m.reconcileMainConfig()
+	if err != nil {
+		return "", err
+	}
+	if after == before {
+		return "", nil
+	}
+	return unifiedDiff(before, after), nil
+}
+
+func readFileOrEmpty(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(b), nil
+}
+
+// findFenceMarker scans cfg.Hosts in file order starting at (fromHost,
+// fromIdx), looking for an *Empty node whose Comment equals want. It
+// returns the host/node indices of the first match, or (-1, -1).
+func findFenceMarker(hosts []*Host, fromHost, fromIdx int, want string) (int, int) {
+	for hi := fromHost; hi < len(hosts); hi++ {
+		start := 0
+		if hi == fromHost {
+			start = fromIdx
+		}
+		for ni := start; ni < len(hosts[hi].Nodes); ni++ {
+			if e, ok := hosts[hi].Nodes[ni].(*Empty); ok && e.Comment == want {
+				return hi, ni
+			}
+		}
+	}
+	return -1, -1
+}
+
+// headerOnly renders just h's "Host ..." declaration line, without any of
+// its Nodes.
+func headerOnly(h *Host) string {
+	bare := &Host{
+		Patterns:     h.Patterns,
+		implicit:     h.implicit,
+		EOLComment:   h.EOLComment,
+		leadingSpace: h.leadingSpace,
+		hasEquals:    h.hasEquals,
+	}
+	return bare.String()
+}
+
+// extractLegacyFence finds the first "# ----- BEGIN name -----" / "# -----
+// END name -----" pair in cfg, removes it (and the lines between) from cfg,
+// and returns those lines' rendered text. The pair doesn't need to live in
+// the same Host: a fence commonly wraps one or more whole Host blocks, with
+// BEGIN trailing the previous Host's own directives and END inside (or at
+// the end of) a later one, so this scans across cfg.Hosts in file order. It
+// returns "" if there's no such fence.
+func extractLegacyFence(cfg *Config, name string) string {
+	beginC := fenceComment("BEGIN", name)
+	endC := fenceComment("END", name)
+
+	beginHost, beginIdx := findFenceMarker(cfg.Hosts, 0, 0, beginC)
+	if beginHost == -1 {
+		return ""
+	}
+	endHost, endIdx := findFenceMarker(cfg.Hosts, beginHost, beginIdx+1, endC)
+	if endHost == -1 {
+		return ""
+	}
+
+	var buf strings.Builder
+	if beginHost == endHost {
+		h := cfg.Hosts[beginHost]
+		for _, n := range h.Nodes[beginIdx+1 : endIdx] {
+			buf.WriteString(n.String())
+			buf.WriteString("\n")
+		}
+		h.Nodes = append(h.Nodes[:beginIdx], h.Nodes[endIdx+1:]...)
+		return buf.String()
+	}
+
+	beginH := cfg.Hosts[beginHost]
+	for _, n := range beginH.Nodes[beginIdx+1:] {
+		buf.WriteString(n.String())
+		buf.WriteString("\n")
+	}
+	beginH.Nodes = beginH.Nodes[:beginIdx]
+
+	for hi := beginHost + 1; hi < endHost; hi++ {
+		buf.WriteString(cfg.Hosts[hi].String())
+	}
+
+	endH := cfg.Hosts[endHost]
+	buf.WriteString(headerOnly(endH))
+	for _, n := range endH.Nodes[:endIdx] {
+		buf.WriteString(n.String())
+		buf.WriteString("\n")
+	}
+
+	kept := append([]*Host{}, cfg.Hosts[:beginHost+1]...)
+	if leftover := endH.Nodes[endIdx+1:]; len(leftover) > 0 {
+		kept = append(kept, &Host{
+			Patterns:     endH.Patterns,
+			implicit:     endH.implicit,
+			EOLComment:   endH.EOLComment,
+			leadingSpace: endH.leadingSpace,
+			hasEquals:    endH.hasEquals,
+			Nodes:        leftover,
+		})
+	}
+	kept = append(kept, cfg.Hosts[endHost+1:]...)
+	cfg.Hosts = kept
+
+	return buf.String()
+}
+
+// ensureIncludeLine adds an "Include ref" KV to cfg's first Host unless one
+// already exists somewhere in cfg, and reports whether it added one.
+func ensureIncludeLine(cfg *Config, ref string) bool {
+	for _, h := range cfg.Hosts {
+		for _, n := range h.Nodes {
+			if kv, ok := n.(*KV); ok && strings.ToLower(kv.Key) == "include" && strings.TrimSpace(kv.Value) == ref {
+				return false
+			}
+		}
+	}
+	if len(cfg.Hosts) == 0 {
+		cfg.Hosts = append(cfg.Hosts, &Host{implicit: true, Patterns: []*Pattern{matchAll}, Nodes: []Node{}})
+	}
+	top := cfg.Hosts[0]
+	top.Nodes = append(top.Nodes, &KV{Key: "Include", Value: ref})
+	return true
+}
+
+// removeIncludeLine removes every "Include ref" KV from cfg.
+func removeIncludeLine(cfg *Config, ref string) {
+	for _, h := range cfg.Hosts {
+		nodes := h.Nodes[:0]
+		for _, n := range h.Nodes {
+			if kv, ok := n.(*KV); ok && strings.ToLower(kv.Key) == "include" && strings.TrimSpace(kv.Value) == ref {
+				continue
+			}
+			nodes = append(nodes, n)
+		}
+		h.Nodes = nodes
+	}
+}
@@ -0,0 +1,155 @@
+package ssh_config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestExtractLegacyFenceAcrossHosts covers the canonical managed layout,
+// where a legacy fence wraps a whole Host block: BEGIN trails the previous
+// Host's own directives and END sits inside the wrapped Host itself, so the
+// two markers live in different cfg.Hosts entries.
+func TestExtractLegacyFenceAcrossHosts(t *testing.T) {
+	other := &Host{
+		Patterns: []*Pattern{mustPattern(t, "other")},
+		Nodes: []Node{
+			&KV{Key: "Foo", Value: "bar", leadingSpace: 2},
+			&Empty{Comment: fenceComment("BEGIN", "work"), leadingSpace: 2},
+		},
+	}
+	wrapped := &Host{
+		Patterns: []*Pattern{mustPattern(t, "coder.*")},
+		Nodes: []Node{
+			&KV{Key: "HostName", Value: "x", leadingSpace: 2},
+			&Empty{Comment: fenceComment("END", "work"), leadingSpace: 2},
+		},
+	}
+	cfg := &Config{Hosts: []*Host{other, wrapped}}
+
+	legacy := extractLegacyFence(cfg, "work")
+	if legacy == "" {
+		t.Fatal("extractLegacyFence returned empty, want the migrated Host block")
+	}
+	if !strings.Contains(legacy, "Host coder.*") || !strings.Contains(legacy, "HostName x") {
+		t.Errorf("legacy = %q, missing the wrapped Host's header or directives", legacy)
+	}
+	if len(cfg.Hosts) != 1 {
+		t.Fatalf("len(cfg.Hosts) after extraction = %d, want 1 (wrapped Host fully removed)", len(cfg.Hosts))
+	}
+	remaining := cfg.Hosts[0].Nodes
+	if len(remaining) != 1 || remaining[0].(*KV).Key != "Foo" {
+		t.Errorf("remaining nodes = %v, want just Foo", remaining)
+	}
+}
+
+// TestExtractLegacyFenceSameHost covers the simpler layout where BEGIN and
+// END both live inside a single Host's Nodes.
+func TestExtractLegacyFenceSameHost(t *testing.T) {
+	h := &Host{
+		Patterns: []*Pattern{mustPattern(t, "wrapper")},
+		Nodes: []Node{
+			&Empty{Comment: fenceComment("BEGIN", "work")},
+			&KV{Key: "Port", Value: "22"},
+			&Empty{Comment: fenceComment("END", "work")},
+		},
+	}
+	cfg := &Config{Hosts: []*Host{h}}
+
+	legacy := extractLegacyFence(cfg, "work")
+	if !strings.Contains(legacy, "Port 22") {
+		t.Errorf("legacy = %q, want it to contain the fenced Port line", legacy)
+	}
+	if len(h.Nodes) != 0 {
+		t.Errorf("h.Nodes after extraction = %v, want empty", h.Nodes)
+	}
+}
+
+// TestUpsertPreservesLegacyContent checks that migrating a legacy fence
+// doesn't drop its content even when the caller is also writing new hosts.
+func TestUpsertPreservesLegacyContent(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := dir + "/config"
+	mainContents := "Host other\n" +
+		"  Foo bar\n" +
+		"  # ----- BEGIN work -----\n" +
+		"Host coder.*\n" +
+		"  HostName x\n" +
+		"  # ----- END work -----\n"
+	if err := writeFileAtomic(mainPath, mainContents, 0600); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+
+	m := &ManagedBlockHandle{path: mainPath, name: "work", includeRef: dir + "/config.d/work"}
+	newHost := &Host{Patterns: []*Pattern{mustPattern(t, "example")}, Nodes: []Node{
+		&KV{Key: "Port", Value: "2222", leadingSpace: 2},
+	}}
+	if _, err := m.Upsert([]*Host{newHost}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	incContents, err := readFileOrEmpty(dir + "/config.d/work")
+	if err != nil {
+		t.Fatalf("readFileOrEmpty: %v", err)
+	}
+	if !strings.Contains(incContents, "Host coder.*") || !strings.Contains(incContents, "HostName x") {
+		t.Errorf("include file = %q, missing migrated legacy content", incContents)
+	}
+	if !strings.Contains(incContents, "Host example") || !strings.Contains(incContents, "Port 2222") {
+		t.Errorf("include file = %q, missing the newly upserted host", incContents)
+	}
+}
+
+// TestUpsertIdempotentAfterMigration checks that a second Upsert call with
+// the same hosts, run after a legacy fence has already been migrated out of
+// the main config on a prior call, is a true no-op: it must not rewrite the
+// include file, and the migrated legacy content must still be there, even
+// though the main config's fence (the only other place that content lived)
+// is long gone by the second call.
+func TestUpsertIdempotentAfterMigration(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := dir + "/config"
+	mainContents := "Host other\n" +
+		"  Foo bar\n" +
+		"  # ----- BEGIN work -----\n" +
+		"Host coder.*\n" +
+		"  HostName x\n" +
+		"  # ----- END work -----\n"
+	if err := writeFileAtomic(mainPath, mainContents, 0600); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+
+	m := &ManagedBlockHandle{path: mainPath, name: "work", includeRef: dir + "/config.d/work"}
+	newHost := &Host{Patterns: []*Pattern{mustPattern(t, "example")}, Nodes: []Node{
+		&KV{Key: "Port", Value: "2222", leadingSpace: 2},
+	}}
+
+	changed1, err := m.Upsert([]*Host{newHost})
+	if err != nil {
+		t.Fatalf("Upsert (run 1): %v", err)
+	}
+	if !changed1 {
+		t.Fatal("changed on run 1 = false, want true")
+	}
+	after1, err := readFileOrEmpty(dir + "/config.d/work")
+	if err != nil {
+		t.Fatalf("readFileOrEmpty: %v", err)
+	}
+
+	changed2, err := m.Upsert([]*Host{newHost})
+	if err != nil {
+		t.Fatalf("Upsert (run 2): %v", err)
+	}
+	if changed2 {
+		t.Error("changed on run 2 = true, want false (identical input should be a no-op)")
+	}
+	after2, err := readFileOrEmpty(dir + "/config.d/work")
+	if err != nil {
+		t.Fatalf("readFileOrEmpty: %v", err)
+	}
+	if after1 != after2 {
+		t.Errorf("include file changed between identical runs:\nrun 1: %q\nrun 2: %q", after1, after2)
+	}
+	if !strings.Contains(after2, "Host coder.*") || !strings.Contains(after2, "HostName x") {
+		t.Errorf("include file after run 2 = %q, lost the migrated legacy content", after2)
+	}
+}
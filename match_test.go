@@ -0,0 +1,82 @@
+package ssh_config
+
+import "testing"
+
+func mustPattern(t *testing.T, s string) *Pattern {
+	t.Helper()
+	p, err := NewPattern(s)
+	if err != nil {
+		t.Fatalf("NewPattern(%q): %v", s, err)
+	}
+	return p
+}
+
+// TestMatchIndependentOfEnclosingHost verifies that a Match nested inside a
+// Host is evaluated against its own criteria, not gated behind the
+// enclosing Host's patterns also matching - the same way ssh treats a
+// Match line as ending the Host block it appears in.
+func TestMatchIndependentOfEnclosingHost(t *testing.T) {
+	// Host foo
+	//   Match host bar
+	//     User admin
+	hostFoo := &Host{
+		Patterns: []*Pattern{mustPattern(t, "foo")},
+		Nodes: []Node{
+			&KV{Key: "Match", Value: "host bar", leadingSpace: 2},
+			&KV{Key: "User", Value: "admin", leadingSpace: 4},
+		},
+	}
+	cfg := &Config{Hosts: []*Host{hostFoo}}
+
+	got, err := cfg.GetWithContext(MatchContext{Alias: "bar"}, "User")
+	if err != nil {
+		t.Fatalf("GetWithContext: %v", err)
+	}
+	if got != "admin" {
+		t.Errorf("GetWithContext(bar, User) = %q, want %q", got, "admin")
+	}
+
+	// foo itself never reaches the Match (its own criteria is "host bar"),
+	// and has no User of its own.
+	got, err = cfg.GetWithContext(MatchContext{Alias: "foo"}, "User")
+	if err != nil {
+		t.Fatalf("GetWithContext: %v", err)
+	}
+	if got != "" {
+		t.Errorf("GetWithContext(foo, User) = %q, want empty", got)
+	}
+}
+
+// TestBlocksSplitHostAtMatch checks that Config.Blocks turns a Host
+// containing an embedded Match line into two Blocks: the Host's own nodes
+// up to the Match, and the Match's own nodes after it.
+func TestBlocksSplitHostAtMatch(t *testing.T) {
+	host := &Host{
+		Patterns: []*Pattern{mustPattern(t, "foo")},
+		Nodes: []Node{
+			&KV{Key: "Port", Value: "22"},
+			&KV{Key: "Match", Value: "host bar"},
+			&KV{Key: "User", Value: "admin"},
+		},
+	}
+	cfg := &Config{Hosts: []*Host{host}}
+
+	blocks := cfg.Blocks()
+	if len(blocks) != 2 {
+		t.Fatalf("len(Blocks()) = %d, want 2", len(blocks))
+	}
+	own, ok := blocks[0].(*Host)
+	if !ok {
+		t.Fatalf("blocks[0] = %T, want *Host", blocks[0])
+	}
+	if len(own.blockNodes()) != 1 {
+		t.Errorf("own host has %d nodes, want 1 (just Port)", len(own.blockNodes()))
+	}
+	m, ok := blocks[1].(*Match)
+	if !ok {
+		t.Fatalf("blocks[1] = %T, want *Match", blocks[1])
+	}
+	if len(m.Nodes) != 1 {
+		t.Errorf("match block has %d nodes, want 1 (just User)", len(m.Nodes))
+	}
+}
@@ -0,0 +1,347 @@
+package ssh_config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultKVIndent is the indentation used for every KV node Set appends to
+// a Host, whatever indentation the Host's existing lines use.
+const defaultKVIndent = 2
+
+// patternsEqual reports whether a Host's patterns are exactly the ones
+// parsed from pattern (same patterns, same order), which is how SetHost and
+// RemoveHost decide whether a Host block already exists for pattern.
+func patternsEqual(h *Host, patterns []*Pattern) bool {
+	if len(h.Patterns) != len(patterns) {
+		return false
+	}
+	for i := range patterns {
+		if h.Patterns[i].String() != patterns[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+func parsePatterns(pattern string) ([]*Pattern, error) {
+	fields := strings.Fields(pattern)
+	if len(fields) == 0 {
+		return nil, errors.New("ssh_config: empty Host pattern")
+	}
+	patterns := make([]*Pattern, len(fields))
+	for i, f := range fields {
+		p, err := NewPattern(f)
+		if err != nil {
+			return nil, err
+		}
+		patterns[i] = p
+	}
+	return patterns, nil
+}
+
+// findHost returns the Host in c.Hosts whose patterns exactly match
+// pattern, or nil if there is none.
+func (c *Config) findHost(patterns []*Pattern) *Host {
+	for _, h := range c.Hosts {
+		if !h.implicit && patternsEqual(h, patterns) {
+			return h
+		}
+	}
+	return nil
+}
+
+// SetHost creates or updates the Host block matching pattern (a
+// space-separated list of patterns, as it would appear after "Host " in a
+// config file) so that it contains kvs, then returns it. Existing keys in
+// the block keep their position, leading whitespace, and any trailing
+// comment; keys not already present are appended in map iteration order.
+// Round-tripping Decode -> SetHost -> String changes only the lines SetHost
+// actually touched.
+func (c *Config) SetHost(pattern string, kvs map[string]string) (*Host, error) {
+	patterns, err := parsePatterns(pattern)
+	if err != nil {
+		return nil, err
+	}
+	host := c.findHost(patterns)
+	if host == nil {
+		host = &Host{Patterns: patterns, Nodes: []Node{}}
+		c.Hosts = append(c.Hosts, host)
+	}
+	for k, v := range kvs {
+		host.Set(k, v)
+	}
+	return host, nil
+}
+
+// RemoveHost removes the Host block matching pattern, the same way
+// SetHost's pattern matching works, and reports whether a block was
+// removed.
+func (c *Config) RemoveHost(pattern string) bool {
+	patterns, err := parsePatterns(pattern)
+	if err != nil {
+		return false
+	}
+	for i, h := range c.Hosts {
+		if !h.implicit && patternsEqual(h, patterns) {
+			c.Hosts = append(c.Hosts[:i], c.Hosts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the first value for key among h's direct Nodes, and whether
+// it was found. Unlike Config.Get, it does not follow Include or evaluate
+// Match - it only looks at the literal key/value lines in this Host block.
+func (h *Host) Get(key string) (string, bool) {
+	lowerKey := strings.ToLower(key)
+	for _, node := range h.Nodes {
+		if kv, ok := node.(*KV); ok && strings.ToLower(kv.Key) == lowerKey {
+			return kv.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set adds or updates the first node for key in h, preserving its leading
+// whitespace and any trailing comment if it already existed. If key isn't
+// present, a new line is appended to h's Nodes using defaultKVIndent.
+func (h *Host) Set(key, value string) {
+	lowerKey := strings.ToLower(key)
+	for _, node := range h.Nodes {
+		if kv, ok := node.(*KV); ok && strings.ToLower(kv.Key) == lowerKey {
+			kv.Value = value
+			return
+		}
+	}
+	h.Nodes = append(h.Nodes, &KV{
+		Key:          key,
+		Value:        value,
+		leadingSpace: defaultKVIndent,
+	})
+}
+
+// Unset removes every node for key from h and reports whether anything was
+// removed.
+func (h *Host) Unset(key string) bool {
+	lowerKey := strings.ToLower(key)
+	removed := false
+	nodes := h.Nodes[:0]
+	for _, node := range h.Nodes {
+		if kv, ok := node.(*KV); ok && strings.ToLower(kv.Key) == lowerKey {
+			removed = true
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	h.Nodes = nodes
+	return removed
+}
+
+// WriteFile writes c.String() to path, replacing its previous contents
+// atomically: the new contents are written to a temp file in the same
+// directory, then renamed over path. path's existing permissions are
+// preserved, or 0644 for a new file.
+func (c *Config) WriteFile(path string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	return writeFileAtomic(path, c.String(), mode)
+}
+
+// writeFileAtomic writes content to path by writing it to a temp file in
+// the same directory and renaming that over path, so a reader never
+// observes a partially written file.
+func writeFileAtomic(path, content string, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".ssh_config-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+// Diff returns a unified diff between c.String() and other.String(), with
+// "a" and "b" file labels, the same way `diff -u` would between two files
+// holding those contents. It returns the empty string if the two serialize
+// identically.
+func (c *Config) Diff(other *Config) string {
+	return unifiedDiff(c.String(), other.String())
+}
+
+// unifiedDiff produces a unified diff between a and b, split into lines.
+// It's a small, dependency-free implementation: an O(n*m) longest-common-
+// subsequence diff followed by the standard 3-line-of-context hunk format.
+func unifiedDiff(a, b string) string {
+	aLines := splitLinesKeepEnd(a)
+	bLines := splitLinesKeepEnd(b)
+	ops := diffLines(aLines, bLines)
+	hunks := buildHunks(ops, 3)
+	if len(hunks) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("--- a\n+++ b\n")
+	for _, h := range hunks {
+		aStart, bStart := h.aStart+1, h.bStart+1
+		if h.aLen == 0 {
+			aStart = 0
+		}
+		if h.bLen == 0 {
+			bStart = 0
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", aStart, h.aLen, bStart, h.bLen)
+		for _, op := range h.ops {
+			buf.WriteString(op.marker)
+			buf.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+func splitLinesKeepEnd(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type diffOp struct {
+	marker string // " ", "-", or "+"
+	line   string
+	aIdx   int // index into aLines, or -1
+	bIdx   int // index into bLines, or -1
+}
+
+// diffLines computes a minimal-ish sequence of diffOps turning aLines into
+// bLines, via a textbook dynamic-programming longest common subsequence.
+func diffLines(aLines, bLines []string) []diffOp {
+	n, m := len(aLines), len(bLines)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if aLines[i] == bLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case aLines[i] == bLines[j]:
+			ops = append(ops, diffOp{" ", aLines[i], i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{"-", aLines[i], i, -1})
+			i++
+		default:
+			ops = append(ops, diffOp{"+", bLines[j], -1, j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{"-", aLines[i], i, -1})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{"+", bLines[j], -1, j})
+	}
+	return ops
+}
+
+type diffHunk struct {
+	aStart, aLen int
+	bStart, bLen int
+	ops          []diffOp
+}
+
+// buildHunks groups a flat diffOp sequence into unified-diff hunks, each
+// keeping up to context lines of unchanged lines around every change and
+// merging hunks that end up overlapping.
+func buildHunks(ops []diffOp, context int) []diffHunk {
+	var hunks []diffHunk
+	i := 0
+	for i < len(ops) {
+		if ops[i].marker == " " {
+			i++
+			continue
+		}
+		start := i
+		for start > 0 && i-start < context && ops[start-1].marker == " " {
+			start--
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].marker != " " {
+				end++
+				continue
+			}
+			// Look ahead: if a run of up to 2*context unchanged lines is
+			// followed by more changes, keep going and merge into one hunk.
+			run := end
+			for run < len(ops) && ops[run].marker == " " && run-end < 2*context {
+				run++
+			}
+			if run < len(ops) && ops[run].marker != " " {
+				end = run
+				continue
+			}
+			if run-end > context {
+				run = end + context
+			}
+			end = run
+			break
+		}
+		hunk := diffHunk{ops: ops[start:end]}
+		for _, op := range hunk.ops {
+			if op.aIdx >= 0 {
+				if hunk.aLen == 0 {
+					hunk.aStart = op.aIdx
+				}
+				hunk.aLen++
+			}
+			if op.bIdx >= 0 {
+				if hunk.bLen == 0 {
+					hunk.bStart = op.bIdx
+				}
+				hunk.bLen++
+			}
+		}
+		hunks = append(hunks, hunk)
+		i = end
+	}
+	return hunks
+}
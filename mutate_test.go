@@ -0,0 +1,50 @@
+package ssh_config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffPureInsertion(t *testing.T) {
+	a := ""
+	b := "Host a\n  Port 1\n"
+	got := unifiedDiff(a, b)
+	want := "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+Host a\n+  Port 1\n"
+	if got != want {
+		t.Errorf("unifiedDiff(%q, %q) = %q, want %q", a, b, got, want)
+	}
+}
+
+func TestUnifiedDiffPureDeletion(t *testing.T) {
+	a := "Host a\n  Port 1\n"
+	b := ""
+	got := unifiedDiff(a, b)
+	want := "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-Host a\n-  Port 1\n"
+	if got != want {
+		t.Errorf("unifiedDiff(%q, %q) = %q, want %q", a, b, got, want)
+	}
+}
+
+func TestUnifiedDiffNoChange(t *testing.T) {
+	a := "Host a\n  Port 1\n"
+	if got := unifiedDiff(a, a); got != "" {
+		t.Errorf("unifiedDiff(a, a) = %q, want empty", got)
+	}
+}
+
+func TestSetHostRoundTrip(t *testing.T) {
+	cfg, err := Decode(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if _, err := cfg.SetHost("example.*", map[string]string{"Port": "2222"}); err != nil {
+		t.Fatalf("SetHost: %v", err)
+	}
+	got, err := cfg.Get("example.com", "Port")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != "2222" {
+		t.Errorf("Get after SetHost = %q, want %q", got, "2222")
+	}
+}